@@ -0,0 +1,57 @@
+// Package driver 定义分布式锁运行所依赖的最小 redis 客户端接口，
+// 具体实现由各自的子包提供（driver/redigo 基于 gomodule/redigo，driver/goredis 基于 go-redis/v9），
+// RedisLock、RedLock 只依赖本包的 Client 接口，不关心背后具体是哪个 redis 客户端库。
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Client 分布式锁运行所依赖的客户端驱动接口
+type Client interface {
+	// SetNEX 语义是 SET key value EX expireSeconds NX，用于非阻塞加锁
+	SetNEX(ctx context.Context, key, value string, expireSeconds int64) (int64, error)
+	// Eval 执行 lua 脚本，用于解锁、续期、可重入计数、fencing token 等需要原子性保证的操作
+	Eval(ctx context.Context, src string, keyCount int, keysAndArgs []interface{}) (interface{}, error)
+	// Subscribe 订阅指定 channel，用于 watch 模式下等待锁释放通知
+	Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error)
+	// PSubscribe 按 pattern 订阅一组 channel
+	PSubscribe(ctx context.Context, pattern string) (<-chan struct{}, func(), error)
+	// Ping 探测节点健康状态；RedLock 在把某个节点纳入仲裁前，可以用它提前剔除已经不可用的节点
+	Ping(ctx context.Context) error
+}
+
+// ValidateHashTags 校验一组 key 是否共享同一个 hash tag（形如 "{tag}"）。
+// Redis Cluster 依据 {} 内的内容计算 slot，多 key 的 lua 脚本必须保证所有 key 落在同一个 slot，
+// 否则会返回 CROSSSLOT 错误；该校验帮助驱动实现在请求发出前就发现这类问题
+func ValidateHashTags(keys []string) error {
+	if len(keys) < 2 {
+		return nil
+	}
+
+	tag, ok := hashTag(keys[0])
+	if !ok {
+		return fmt.Errorf("ERROR: multi-key script requires a {tag} in each key, got %q", keys[0])
+	}
+	for _, key := range keys[1:] {
+		t, ok := hashTag(key)
+		if !ok || t != tag {
+			return fmt.Errorf("ERROR: multi-key script keys must share the same {tag}, %q and %q don't match", keys[0], key)
+		}
+	}
+	return nil
+}
+
+func hashTag(key string) (string, bool) {
+	start := strings.Index(key, "{")
+	if start < 0 {
+		return "", false
+	}
+	end := strings.Index(key[start+1:], "}")
+	if end <= 0 {
+		return "", false
+	}
+	return key[start+1 : start+1+end], true
+}
@@ -0,0 +1,26 @@
+package driver
+
+import "testing"
+
+func Test_ValidateHashTags(t *testing.T) {
+	cases := []struct {
+		name    string
+		keys    []string
+		wantErr bool
+	}{
+		{name: "single key never needs a tag", keys: []string{"REDIS_LOCK_PREFIX_foo"}, wantErr: false},
+		{name: "matching tags", keys: []string{"{foo}_lock", "{foo}_fence"}, wantErr: false},
+		{name: "mismatched tags", keys: []string{"{foo}_lock", "{bar}_fence"}, wantErr: true},
+		{name: "missing tag", keys: []string{"foo_lock", "foo_fence"}, wantErr: true},
+		{name: "empty tag", keys: []string{"{}_lock", "{}_fence"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateHashTags(c.keys)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("ValidateHashTags(%v) err = %v, wantErr %v", c.keys, err, c.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package goredis 基于 github.com/redis/go-redis/v9 实现 driver.Client 接口，
+// 适用于已经在使用 go-redis 的场景，也是接入 Redis Cluster、Ring 的推荐方式
+package goredis
+
+import (
+	"context"
+	"errors"
+	"redigo_lock/driver"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cmdable 是 go-redis 中 Client / ClusterClient / Ring 共同实现的命令集合子集，
+// 通过面向该接口编程，Client 可以不关心背后具体是单节点、Cluster 还是 Ring
+type cmdable interface {
+	redis.Cmdable
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	PSubscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// Client go-redis 驱动的客户端实现，持有方可以是 *redis.Client、*redis.ClusterClient 或 *redis.Ring
+type Client struct {
+	cmd cmdable
+}
+
+// NewClient 使用单节点 *redis.Client 构造
+func NewClient(cli *redis.Client) *Client {
+	return &Client{cmd: cli}
+}
+
+// NewClusterClient 使用 *redis.ClusterClient 构造，用于接入 Redis Cluster
+func NewClusterClient(cli *redis.ClusterClient) *Client {
+	return &Client{cmd: cli}
+}
+
+// NewRingClient 使用 *redis.Ring 构造，用于接入客户端分片
+func NewRingClient(cli *redis.Ring) *Client {
+	return &Client{cmd: cli}
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.cmd.Ping(ctx).Err()
+}
+
+func (c *Client) SetNEX(ctx context.Context, key, value string, expireSeconds int64) (int64, error) {
+	ok, err := c.cmd.SetNX(ctx, key, value, time.Duration(expireSeconds)*time.Second).Result()
+	if err != nil {
+		return -1, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Eval 执行 lua 脚本；当脚本涉及多个 key 时，会先校验这些 key 是否共享同一个 hash tag，
+// 避免在 Redis Cluster 下因为 key 分散在不同 slot 而触发 CROSSSLOT 错误
+func (c *Client) Eval(ctx context.Context, src string, keyCount int, keysAndArgs []interface{}) (interface{}, error) {
+	if keyCount > len(keysAndArgs) {
+		return nil, errors.New("ERROR: keyCount is greater than the number of keysAndArgs")
+	}
+
+	keys := make([]string, keyCount)
+	for i := 0; i < keyCount; i++ {
+		key, ok := keysAndArgs[i].(string)
+		if !ok {
+			return nil, errors.New("ERROR: key must be a string")
+		}
+		keys[i] = key
+	}
+	if err := driver.ValidateHashTags(keys); err != nil {
+		return nil, err
+	}
+
+	reply, err := c.cmd.Eval(ctx, src, keys, keysAndArgs[keyCount:]...).Result()
+	if errors.Is(err, redis.Nil) {
+		// go-redis 把 RESP nil 回复当作 redis.Nil 错误返回，而不是像 redigo 那样 (nil, nil)；
+		// 统一翻译成 redigo 的语义，这样上层 lock 逻辑（例如 LuaReentrantLock 用 nil 表示加锁成功）不用感知驱动差异
+		return nil, nil
+	}
+	return reply, err
+}
+
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error) {
+	pubsub := c.cmd.Subscribe(ctx, channel)
+	return notifyChannel(pubsub)
+}
+
+func (c *Client) PSubscribe(ctx context.Context, pattern string) (<-chan struct{}, func(), error) {
+	pubsub := c.cmd.PSubscribe(ctx, pattern)
+	return notifyChannel(pubsub)
+}
+
+func notifyChannel(pubsub *redis.PubSub) (<-chan struct{}, func(), error) {
+	msgCh := pubsub.Channel()
+
+	notifyCh := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case _, ok := <-msgCh:
+				if !ok {
+					return
+				}
+				// 只起到“唤醒等待者去重新抢锁”的作用，因此只需要保证 channel 不阻塞即可，无需关注具体消息内容
+				select {
+				case notifyCh <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	cancel := func() {
+		close(done)
+		pubsub.Close()
+	}
+
+	return notifyCh, cancel, nil
+}
+
@@ -0,0 +1,286 @@
+// Package redigo 基于 github.com/gomodule/redigo/redis 实现 driver.Client 接口，
+// 是 redigo_lock 默认使用的驱动
+package redigo
+
+import (
+	"context"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"time"
+)
+
+// Client redigo 驱动的客户端实现
+type Client struct {
+	ClientOptions
+	pool *redis.Pool
+}
+
+func NewClient(network, address, password string, opts ...ClientOption) *Client {
+	client := Client{
+		ClientOptions: ClientOptions{
+			network:  network,
+			address:  address,
+			password: password,
+		},
+	}
+
+	// 将额外参数注入到ClientOption中
+	for _, opt := range opts {
+		opt(&client.ClientOptions)
+	}
+
+	setClientArgs(&client.ClientOptions)
+
+	client.pool = client.getRedisPool()
+	return &client
+}
+
+// getRedisPool 获取连接池
+func (c *Client) getRedisPool() *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			c, err := c.getRedisConn()
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+		MaxIdle:     c.maxIdle,
+		MaxActive:   c.maxActive,
+		IdleTimeout: time.Duration(c.idleTimeoutSeconds) * time.Second,
+		Wait:        c.wait,
+	}
+}
+
+func (c *Client) getRedisConn() (redis.Conn, error) {
+	if c.address == "" {
+		panic("ERROR: Empty redis address!")
+	}
+
+	// 配置redis连接参数
+	var opts []redis.DialOption
+	if len(c.password) > 0 {
+		opts = append(opts, redis.DialPassword(c.password))
+	}
+
+	conn, err := redis.DialContext(context.Background(), c.network, c.address, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, err
+}
+
+// Ping 探测节点是否健康
+func (c *Client) Ping(ctx context.Context) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("PING")
+	return err
+}
+
+func (c *Client) SetNX(ctx context.Context, key, value string, expireSeconds int64) (int64, error) {
+	if key == "" || value == "" {
+		return -1, errors.New("ERROR: redis key or value may be empty!")
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do("SET", key, value, "NX")
+	if err != nil {
+		return -1, err
+	}
+
+	if resp, ok := reply.(string); ok && strings.ToLower(resp) == "ok" {
+		return 1, nil
+	}
+
+	// 存在空指针异常：https://github.com/xiaoxuxiansheng/redis_lock/issues/2
+	r, _ := reply.(int64)
+	return r, err
+}
+
+func (c *Client) Eval(ctx context.Context, src string, keyCount int, keysAndArgs []interface{}) (interface{}, error) {
+	args := make([]interface{}, 2+len(keysAndArgs))
+	args[0] = src
+	args[1] = keyCount
+	copy(args[2:], keysAndArgs)
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	// Redis Eval 命令使用 Lua 解释器执行脚本
+	return conn.Do("EVAL", args...)
+}
+
+// Subscribe 订阅指定 channel，当有消息发布时，会向返回的 channel 中投递一个通知
+// 返回的 cancel 函数用于主动取消订阅、归还连接，调用方必须保证调用
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error) {
+	return c.subscribe(ctx, false, channel)
+}
+
+// PSubscribe 按照 redis 的 pattern 匹配规则订阅一组 channel
+func (c *Client) PSubscribe(ctx context.Context, pattern string) (<-chan struct{}, func(), error) {
+	return c.subscribe(ctx, true, pattern)
+}
+
+func (c *Client) subscribe(ctx context.Context, isPattern bool, channel string) (<-chan struct{}, func(), error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	if isPattern {
+		err = psc.PSubscribe(channel)
+	} else {
+		err = psc.Subscribe(channel)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	notifyCh := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			switch psc.Receive().(type) {
+			// redigo 把普通订阅和 pattern 订阅的消息都投递为 redis.Message（pattern 订阅时会额外带上 .Pattern 字段）
+			case redis.Message:
+				// 只起到“唤醒等待者去重新抢锁”的作用，因此只需要保证 channel 不阻塞即可，无需关注具体消息内容
+				select {
+				case notifyCh <- struct{}{}:
+				default:
+				}
+			case error:
+				return
+			}
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+
+	cancel := func() {
+		close(done)
+		if isPattern {
+			psc.PUnsubscribe(channel)
+		} else {
+			psc.Unsubscribe(channel)
+		}
+		conn.Close()
+	}
+
+	return notifyCh, cancel, nil
+}
+
+// 其他命令的实现
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("ERROR: redis GET key can't be empty!")
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do("GET", key))
+}
+
+func (c *Client) Set(ctx context.Context, key, value string) (int64, error) {
+	if key == "" || value == "" {
+		return -1, errors.New("ERROR: redis SET key or value can't be empty")
+	}
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	resp, err := conn.Do("SET", key, value)
+	if err != nil {
+		return -1, err
+	}
+
+	if respStr, ok := resp.(string); ok && strings.ToLower(respStr) == "ok" {
+		return 1, nil
+	}
+
+	r, _ := resp.(int64)
+	return r, err
+}
+
+func (c *Client) SetNEX(ctx context.Context, key, value string, expireSeconds int64) (int64, error) {
+	if key == "" || value == "" {
+		return -1, errors.New("ERROR: redis SET keyNX or value can't be empty")
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do("SET", key, value, "EX", expireSeconds, "NX")
+	if err != nil {
+		return -1, err
+	}
+
+	if respStr, ok := reply.(string); ok && strings.ToLower(respStr) == "ok" {
+		return 1, nil
+	}
+
+	r, _ := reply.(int64)
+	return r, err
+}
+
+func (c *Client) Del(ctx context.Context, key string) error {
+	if key == "" {
+		return errors.New("ERROR: redis DEL key can't be empty")
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", key)
+	return err
+}
+
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	if key == "" {
+		return -1, errors.New("ERROR: redis INCR key can't be empty")
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	return redis.Int64(conn.Do("INCR", key))
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/gomodule/redigo/redis"
+	"math/rand"
 	"redigo_lock/utils"
 	"sync/atomic"
 	"time"
@@ -12,8 +12,21 @@ import (
 
 const RedisLockKeyPrefix = "REDIS_LOCK_PREFIX_"
 
+// RedisLockChannelPrefix watch 模式下，锁释放通知使用的 Pub/Sub channel 前缀
+const RedisLockChannelPrefix = "REDIS_LOCK_CHANNEL_"
+
+// RedisLockFenceKeyPrefix fencing token 计数器 key 前缀
+const RedisLockFenceKeyPrefix = "REDIS_LOCK_FENCE_"
+
 var ErrLockAcquiredByOthers = errors.New("This lock is acquired by others")
-var ErrNil = redis.ErrNil
+
+// ErrNil 表示 "nil" 响应（例如 key 不存在）的哨兵错误，不绑定具体的驱动实现；
+// driver 子包的驱动不会返回该值（例如 driver/redigo 的 Get 返回的是 redis.ErrNil）
+var ErrNil = errors.New("redigo_lock: nil returned")
+
+// ErrReentrantFencingUnsupported reentrant 基于 hash 计数器存储锁，fencing 则把 token 拼接进字符串 value，
+// 两种存储格式互斥，因此不支持同时开启 WithReentrant() 和 WithFencing()
+var ErrReentrantFencingUnsupported = errors.New("ERROR: WithReentrant() and WithFencing() can not be used together")
 
 // IsRetryableErr 不可重入错误
 func IsRetryableErr(err error) bool {
@@ -30,13 +43,33 @@ type RedisLock struct {
 	runningDog int32
 	// watchdog终止
 	stopDog context.CancelFunc
+
+	// fenceToken 开启 fencing 模式后，最近一次加锁成功返回的单调递增 token
+	fenceToken int64
 }
 
 func (r *RedisLock) getLockKey() string {
 	return RedisLockKeyPrefix + r.key
 }
 
-func NewRedisLock(key string, client LockClient, opts ...LockOption) *RedisLock {
+func (r *RedisLock) getLockChannel() string {
+	return RedisLockChannelPrefix + r.key
+}
+
+func (r *RedisLock) getLockFenceKey() string {
+	return RedisLockFenceKeyPrefix + r.key
+}
+
+// getStoredValue 返回写入锁 key 的 value：未开启 fencing 时就是 token 本身；
+// 开启 fencing 后，加锁脚本会把 fencing token 追加进 value，解锁/续期时需要带上同样的值才能校验通过
+func (r *RedisLock) getStoredValue() string {
+	if !r.fencing {
+		return r.token
+	}
+	return fmt.Sprintf("%s:%d", r.token, r.fenceToken)
+}
+
+func NewRedisLock(key string, client LockClient, opts ...LockOption) (*RedisLock, error) {
 	lock := RedisLock{
 		key:    key,
 		token:  utils.GetProcessAndGoroutineIDStr(),
@@ -48,7 +81,16 @@ func NewRedisLock(key string, client LockClient, opts ...LockOption) *RedisLock
 	}
 
 	setLockOptions(&lock.LockOptions)
-	return &lock
+
+	if lock.reentrant && lock.fencing {
+		return nil, ErrReentrantFencingUnsupported
+	}
+
+	// 未显式指定 owner 时，默认取 token（进程+协程维度），此时重入仅能在同一协程内生效
+	if lock.owner == "" {
+		lock.owner = lock.token
+	}
+	return &lock, nil
 }
 
 // Lock 加锁
@@ -83,6 +125,13 @@ func (r *RedisLock) Lock(ctx context.Context) (err error) {
 }
 
 func (r *RedisLock) tryLock(ctx context.Context) error {
+	if r.reentrant {
+		return r.tryReentrantLock(ctx)
+	}
+	if r.fencing {
+		return r.tryLockWithFence(ctx)
+	}
+
 	reply, err := r.client.SetNEX(ctx, r.getLockKey(), r.token, r.expireSeconds)
 	if err != nil {
 		return err
@@ -94,6 +143,59 @@ func (r *RedisLock) tryLock(ctx context.Context) error {
 	return nil
 }
 
+// tryLockWithFence 加锁的同时原子地分配一个单调递增的 fencing token
+func (r *RedisLock) tryLockWithFence(ctx context.Context) error {
+	keysAndArgs := []interface{}{
+		r.getLockKey(),
+		r.token,
+		r.expireSeconds,
+		r.getLockFenceKey(),
+	}
+	reply, err := r.client.Eval(ctx, LuaLockWithFence, 1, keysAndArgs)
+	if err != nil {
+		return err
+	}
+
+	fence, _ := reply.(int64)
+	if fence < 0 {
+		return fmt.Errorf("reply: %d, err: %w", fence, ErrLockAcquiredByOthers)
+	}
+
+	r.fenceToken = fence
+	return nil
+}
+
+// LockWithFence 加锁并返回本次加锁得到的 fencing token，需配合 WithFencing() 使用。
+// 下游存储可以据此拒绝来自已过期锁持有者的写入，以应对持有者 GC pause 超过锁 TTL 的场景
+func (r *RedisLock) LockWithFence(ctx context.Context) (int64, error) {
+	if !r.fencing {
+		return 0, errors.New("ERROR: fencing token is not enabled, use WithFencing() option")
+	}
+	if err := r.Lock(ctx); err != nil {
+		return 0, err
+	}
+	return r.fenceToken, nil
+}
+
+// tryReentrantLock 可重入模式下的加锁：锁不存在或已被同一 owner 持有时，重入计数 +1 并续期
+func (r *RedisLock) tryReentrantLock(ctx context.Context) error {
+	keysAndArgs := []interface{}{
+		r.getLockKey(),
+		r.owner,
+		r.expireSeconds * 1000,
+	}
+	reply, err := r.client.Eval(ctx, LuaReentrantLock, 1, keysAndArgs)
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		return nil
+	}
+
+	ttl, _ := reply.(int64)
+	return fmt.Errorf("reply: %d, err: %w", ttl, ErrLockAcquiredByOthers)
+}
+
 // watchDog 启动看门狗
 func (r *RedisLock) watchDog(ctx context.Context) {
 	if !r.watchDogMode {
@@ -116,29 +218,33 @@ func (r *RedisLock) watchDog(ctx context.Context) {
 }
 
 func (r *RedisLock) runWatchDog(ctx context.Context) {
-	ticker := time.NewTicker(WatchDogWorkStepSeconds * time.Second)
+	// 续约间隔与锁的过期时长挂钩（expireSeconds * renewalRatio，默认 expireSeconds 的 1/3），而不是固定写死的常量
+	renewalInterval := time.Duration(float64(r.expireSeconds) * r.renewalRatio * float64(time.Second))
+	ticker := time.NewTicker(renewalInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-
+		case <-ticker.C:
+			// 看门狗负责在用户未显示解锁时，持续为分布式锁进行续约
+			// 通过 lua 脚本，续约之前会确保锁仍然属于自己；每次续约都将过期时长重置为 expireSeconds，
+			// 两次续约之间锁的剩余存活时间会随时间递减，renewalRatio >= 1 时锁可能在下一次续约前过期
+			_ = r.DelayExpire(ctx, r.expireSeconds)
 		}
-
-		// 看门狗负责在用户未显示解锁时，持续为分布式锁进行续约
-		// 通过 lua 脚本，延期之前会确保保证锁仍然属于自己
-		// 为避免因为网络延迟而导致锁被提前释放的问题，watch dog 续约时需要把锁的过期时长额外增加 5 s
-		_ = r.DelayExpire(ctx, WatchDogWorkStepSeconds+5)
 	}
 }
 
 // DelayExpire 延长锁的过期实践，通过lua脚本实现操作原子性
 func (r *RedisLock) DelayExpire(ctx context.Context, expireSeconds int64) error {
+	if r.reentrant {
+		return r.delayExpireReentrant(ctx, expireSeconds)
+	}
+
 	keysAndArgs := []interface{}{
 		r.getLockKey(),
-		r.token,
+		r.getStoredValue(),
 		expireSeconds,
 	}
 	// 执行lua脚本
@@ -154,38 +260,122 @@ func (r *RedisLock) DelayExpire(ctx context.Context, expireSeconds int64) error
 	return nil
 }
 
+// delayExpireReentrant 可重入模式下的续期，仅当锁仍归属该 owner 时才允许续期
+func (r *RedisLock) delayExpireReentrant(ctx context.Context, expireSeconds int64) error {
+	keysAndArgs := []interface{}{
+		r.getLockKey(),
+		r.owner,
+		expireSeconds * 1000,
+	}
+	reply, err := r.client.Eval(ctx, LuaReentrantRenew, 1, keysAndArgs)
+	if err != nil {
+		return err
+	}
+
+	if code, _ := reply.(int64); code != 1 {
+		return errors.New("ERROR: Can not expire lock without ownership of lock")
+	}
+
+	return nil
+}
+
 func (r *RedisLock) tryGetBlockingLock(ctx context.Context) error {
+	// watch 模式下，通过订阅释放通知来等待锁，避免轮询带来的网络开销
+	if r.watchMode {
+		return r.tryGetWatchLock(ctx)
+	}
+
 	// 阻塞模式等待时间上限
 	timeoutCh := time.After(time.Duration(r.blockWaitingSeconds) * time.Second)
-	// 轮询ticker，每隔50ms尝试获取锁一次
-	ticker := time.NewTicker(time.Duration(50) * time.Millisecond)
-	defer ticker.Stop()
 
-	for range ticker.C {
+	// 轮询重试采用指数退避 + full jitter，避免固定间隔轮询在锁竞争激烈时产生惊群效应，
+	// 也避免持有者提前释放后所有等待者仍要傻等满一个固定 tick 才能感知到
+	for attempt := 0; ; attempt++ {
+		err := r.tryLock(ctx)
+		if err == nil {
+			// 加锁成功
+			return nil
+		}
+
+		// 不可重试类型错误，直接返回
+		if !IsRetryableErr(err) {
+			return err
+		}
+
+		timer := time.NewTimer(fullJitterBackoff(r.retryBackoffBase, r.retryBackoffCap, attempt))
 		select {
 		// ctx终止
 		case <-ctx.Done():
+			timer.Stop()
 			return fmt.Errorf("ERROR: Lock failed, ctx timeout, err: %w", ctx.Err())
 		case <-timeoutCh:
+			timer.Stop()
 			return fmt.Errorf("ERROR: Block waiting time out, err: %w", ErrLockAcquiredByOthers)
-		default:
-			// 放行
+		case <-timer.C:
+			// 放行，进入下一轮重试
 		}
+	}
+}
+
+// fullJitterBackoff 计算指数退避 + full jitter 的等待时长：rand(0, min(cap, base * 2^attempt))
+// attempt 过大时 base * 2^attempt 会溢出，因此提前按 cap 夹住
+func fullJitterBackoff(base, cap time.Duration, attempt int) time.Duration {
+	if attempt > 30 {
+		attempt = 30
+	}
+
+	backoff := base << uint(attempt)
+	if backoff <= 0 || backoff > cap {
+		backoff = cap
+	}
 
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// tryGetWatchLock 在抢锁失败后订阅释放通知 channel，收到通知再重新尝试抢锁，
+// 而不是固定间隔轮询。为缓解多个等待者在同一时刻收到通知后的惊群效应，
+// 重新抢锁前会叠加一段随机抖动；更彻底的方案是基于 LPUSH/BRPOPLPUSH 维护一个公平的等待队列，
+// 但代价是实现复杂度和额外的 list 结构维护，此处先采用更轻量的抖动方案
+func (r *RedisLock) tryGetWatchLock(ctx context.Context) error {
+	timeoutCh := time.After(time.Duration(r.blockWaitingSeconds) * time.Second)
+
+	for {
 		err := r.tryLock(ctx)
 		if err == nil {
-			// 加锁成功
 			return nil
 		}
-
-		// 不可重试类型错误，直接返回
 		if !IsRetryableErr(err) {
 			return err
 		}
-	}
 
-	// 不可达
-	return nil
+		notifyCh, cancel, subErr := r.client.Subscribe(ctx, r.getLockChannel())
+		if subErr != nil {
+			return subErr
+		}
+
+		// Subscribe 建立完成之前持有者可能已经 Unlock 并发布了释放通知，
+		// 此处立即重试一次，避免那个窗口期的通知被错过、等待者傻等到超时
+		if err := r.tryLock(ctx); err == nil {
+			cancel()
+			return nil
+		} else if !IsRetryableErr(err) {
+			cancel()
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			return fmt.Errorf("ERROR: Lock failed, ctx timeout, err: %w", ctx.Err())
+		case <-timeoutCh:
+			cancel()
+			return fmt.Errorf("ERROR: Block waiting time out, err: %w", ErrLockAcquiredByOthers)
+		case <-notifyCh:
+			cancel()
+			// 随机抖动后再重新抢锁，避免所有等待者同时重试
+			time.Sleep(time.Duration(rand.Int63n(WatchModeJitterMilliseconds)) * time.Millisecond)
+		}
+	}
 }
 
 func (r *RedisLock) Unlock(ctx context.Context) error {
@@ -195,9 +385,14 @@ func (r *RedisLock) Unlock(ctx context.Context) error {
 		}
 	}()
 
+	if r.reentrant {
+		return r.unlockReentrant(ctx)
+	}
+
 	keysAndArgs := []interface{}{
 		r.getLockKey(),
-		r.token,
+		r.getStoredValue(),
+		r.getLockChannel(),
 	}
 	reply, err := r.client.Eval(ctx, LuaCheckAndDeleteDistributionLock, 1, keysAndArgs)
 	if err != nil {
@@ -210,3 +405,23 @@ func (r *RedisLock) Unlock(ctx context.Context) error {
 
 	return nil
 }
+
+// unlockReentrant 可重入模式下的解锁：重入计数 -1，计数未归零时只续期，归零后才真正删除锁
+func (r *RedisLock) unlockReentrant(ctx context.Context) error {
+	keysAndArgs := []interface{}{
+		r.getLockKey(),
+		r.owner,
+		r.expireSeconds * 1000,
+		r.getLockChannel(),
+	}
+	reply, err := r.client.Eval(ctx, LuaReentrantUnlock, 1, keysAndArgs)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil {
+		return errors.New("ERROR: Can not unlock without ownership of lock")
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -14,8 +15,14 @@ const (
 
 func Test_tryGetBlockingLock(t *testing.T) {
 	client := NewClient("tcp", addr, passwd)
-	lock1 := NewRedisLock("test_key", client, WithExpireSeconds(1))
-	lock2 := NewRedisLock("test_key", client, WithBlock(), WithBlockWaitingSeconds(2))
+	lock1, err := NewRedisLock("test_key", client, WithExpireSeconds(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock2, err := NewRedisLock("test_key", client, WithBlock(), WithBlockWaitingSeconds(2))
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	ctx := context.Background()
 	var wg sync.WaitGroup
@@ -45,8 +52,14 @@ func Test_tryGetBlockingLock(t *testing.T) {
 
 func Test_nonblockingLock(t *testing.T) {
 	client := NewClient("tcp", addr, passwd)
-	lock1 := NewRedisLock("test_key", client, WithExpireSeconds(1))
-	lock2 := NewRedisLock("test_key", client)
+	lock1, err := NewRedisLock("test_key", client, WithExpireSeconds(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock2, err := NewRedisLock("test_key", client)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	ctx := context.Background()
 	var wg sync.WaitGroup
@@ -71,3 +84,38 @@ func Test_nonblockingLock(t *testing.T) {
 	wg.Wait()
 	t.Log("success")
 }
+
+func Test_fullJitterBackoff(t *testing.T) {
+	const base = 10 * time.Millisecond
+	const cap = 100 * time.Millisecond
+
+	for attempt := 0; attempt <= 40; attempt++ {
+		backoff := fullJitterBackoff(base, cap, attempt)
+		if backoff < 0 || backoff > cap {
+			t.Fatalf("attempt %d: got backoff %v, expect within [0, %v]", attempt, backoff, cap)
+		}
+	}
+
+	// attempt 较大时 base * 2^attempt 会溢出，此时应当夹到 cap，而不是返回负数
+	if backoff := fullJitterBackoff(base, cap, 63); backoff < 0 || backoff > cap {
+		t.Fatalf("got backoff %v, expect within [0, %v]", backoff, cap)
+	}
+}
+
+func Test_NewRedisLock_reentrantFencingMutuallyExclusive(t *testing.T) {
+	client := NewClient("tcp", addr, passwd)
+	if _, err := NewRedisLock("test_key", client, WithReentrant(), WithFencing()); !errors.Is(err, ErrReentrantFencingUnsupported) {
+		t.Fatalf("got err: %v, expect: %v", err, ErrReentrantFencingUnsupported)
+	}
+}
+
+func Test_NewRedLock_reentrantFencingMutuallyExclusive(t *testing.T) {
+	confs := []*SingleNodeConf{
+		{Network: "tcp", Address: addr, Password: passwd},
+		{Network: "tcp", Address: addr, Password: passwd},
+		{Network: "tcp", Address: addr, Password: passwd},
+	}
+	if _, err := NewRedLock("test_key", confs, WithRedLockReentrant(""), WithRedLockFencing()); !errors.Is(err, ErrReentrantFencingUnsupported) {
+		t.Fatalf("got err: %v, expect: %v", err, ErrReentrantFencingUnsupported)
+	}
+}
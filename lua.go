@@ -0,0 +1,92 @@
+package redigo_lock
+
+// LuaCheckAndExpireDistributionLock 校验锁是否仍归属自己，是则对其续期
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者的 token
+// ARGV[2]: 续期时长（秒）
+const LuaCheckAndExpireDistributionLock = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("expire", KEYS[1], ARGV[2])
+else
+    return 0
+end
+`
+
+// LuaCheckAndDeleteDistributionLock 校验锁是否仍归属自己，是则将其删除
+// 删除成功后，会向锁对应的释放通知 channel 发布一条消息，唤醒阻塞等待的 watch 模式调用方
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者的 token
+// ARGV[2]: 锁释放后用于通知等待者的 channel
+const LuaCheckAndDeleteDistributionLock = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    local result = redis.call("DEL", KEYS[1])
+    redis.call("PUBLISH", ARGV[2], "1")
+    return result
+else
+    return 0
+end
+`
+
+// LuaLockWithFence 加锁的同时对 fencing token 计数器执行 INCR，并将其拼接进锁的 value 中，
+// 二者在同一段脚本内原子完成。加锁失败时返回 -1
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者的 token
+// ARGV[2]: 过期时长（秒）
+// ARGV[3]: fencing token 计数器 key
+const LuaLockWithFence = `
+if redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[2], "NX") then
+    local fence = redis.call("INCR", ARGV[3])
+    redis.call("APPEND", KEYS[1], ":" .. fence)
+    return fence
+end
+return -1
+`
+
+// LuaReentrantLock 可重入锁加锁脚本：锁不存在，或锁属于自己（HEXISTS），则对持有者的重入计数 +1 并续期；
+// 否则说明锁被他人持有，返回剩余存活时间（毫秒），供调用方判断重试间隔
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者标识（owner）
+// ARGV[2]: 过期时长（毫秒）
+const LuaReentrantLock = `
+if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+    redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return nil
+else
+    return redis.call("PTTL", KEYS[1])
+end
+`
+
+// LuaReentrantRenew 可重入锁续约脚本：仅当锁仍归属该 owner 时才允许续期
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者标识（owner）
+// ARGV[2]: 过期时长（毫秒）
+const LuaReentrantRenew = `
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+    return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`
+
+// LuaReentrantUnlock 可重入锁解锁脚本：对持有者的重入计数 -1；计数仍大于 0 说明还有未归还的重入次数，
+// 只需续期不能删除；计数归零后才真正删除锁，并发布释放通知唤醒 watch 模式等待者
+// KEYS[1]: 锁的 key
+// ARGV[1]: 锁持有者标识（owner）
+// ARGV[2]: 过期时长（毫秒），用于计数未归零时续期
+// ARGV[3]: 锁释放后用于通知等待者的 channel
+const LuaReentrantUnlock = `
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+    return nil
+end
+
+local count = redis.call("HINCRBY", KEYS[1], ARGV[1], -1)
+if count > 0 then
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return 0
+end
+
+redis.call("DEL", KEYS[1])
+redis.call("PUBLISH", ARGV[3], "1")
+return 1
+`
@@ -1,71 +1,29 @@
 package redigo_lock
 
-import "time"
+import (
+	"redigo_lock/driver/redigo"
+	"time"
+)
 
 const (
-	// 默认连接池超过 10 s 释放连接
-	DefaultIdleTimeoutSeconds = 10
-	// 默认最大激活连接数
-	DefaultMaxActive = 100
-	// 默认最大空闲连接数
-	DefaultMaxIdle = 20
-
 	// 默认的分布式锁过期时间
 	DefaultLockExpireSeconds = 30
-	// 看门狗工作时间间隙
-	WatchDogWorkStepSeconds = 10
+	// watch 模式下，收到释放通知后抢锁前的随机抖动上限，用于缓解惊群效应
+	WatchModeJitterMilliseconds = 50
 )
 
-// 相关配置信息
-type ClientOptions struct {
-	maxIdle            int
-	idleTimeoutSeconds int
-	maxActive          int
-	wait               bool
-	// 必填参数
-	network  string
-	address  string
-	password string
-}
-
-// 定义处理ClientOptions的匿名函数
-// 定义匿名函数方便后续进行“链式调用”
-type ClientOption func(c *ClientOptions)
+// DefaultClockDriftFactor RedLock 计算 validity 时默认使用的时钟漂移系数，取经典 Redlock 算法推荐值 1%
+const DefaultClockDriftFactor = 0.01
 
-// setClientArgs 设置默认参数
-func setClientArgs(c *ClientOptions) {
-	if c.maxIdle < 0 {
-		c.maxIdle = DefaultMaxIdle
-	}
-	if c.idleTimeoutSeconds < 0 {
-		c.idleTimeoutSeconds = DefaultIdleTimeoutSeconds
-	}
-	if c.maxActive < 0 {
-		c.maxActive = DefaultMaxActive
-	}
-}
+// DefaultRenewalRatio 看门狗默认按 expireSeconds 的 1/3 作为续约间隔，保证续约节奏快于锁过期
+const DefaultRenewalRatio = 1.0 / 3
 
-// 参数设置函数
-func WithMaxIdle(maxIdle int) ClientOption {
-	return func(c *ClientOptions) {
-		c.maxIdle = maxIdle
-	}
-}
-func WithIdleTimeoutSeconds(idleTimeoutSeconds int) ClientOption {
-	return func(c *ClientOptions) {
-		c.idleTimeoutSeconds = idleTimeoutSeconds
-	}
-}
-func WithMaxActive(maxActive int) ClientOption {
-	return func(c *ClientOptions) {
-		c.maxActive = maxActive
-	}
-}
-func WithWaitMode() ClientOption {
-	return func(c *ClientOptions) {
-		c.wait = true
-	}
-}
+const (
+	// DefaultRetryBackoffBase 阻塞模式重试退避的基准时长
+	DefaultRetryBackoffBase = 20 * time.Millisecond
+	// DefaultRetryBackoffCap 阻塞模式重试退避的时长上限
+	DefaultRetryBackoffCap = 500 * time.Millisecond
+)
 
 // LockOptions 分布式锁配置
 type LockOptions struct {
@@ -73,6 +31,19 @@ type LockOptions struct {
 	blockWaitingSeconds int64
 	expireSeconds       int64
 	watchDogMode        bool
+	// watchMode 为 true 时，阻塞模式下获取锁失败会改为订阅 Pub/Sub 释放通知，而非轮询重试
+	watchMode bool
+	// reentrant 为 true 时，同一个 owner 可以重复加锁，需调用相同次数的 Unlock 才会真正释放
+	reentrant bool
+	// owner 锁持有者标识，reentrant 模式下用于判断重入归属；留空时默认取锁的 token（进程+协程维度）
+	owner string
+	// fencing 为 true 时，加锁成功会额外返回一个单调递增的 fencing token
+	fencing bool
+	// renewalRatio 看门狗续约间隔相对 expireSeconds 的比例，renewal interval = expireSeconds * renewalRatio
+	renewalRatio float64
+	// retryBackoffBase/retryBackoffCap 阻塞模式轮询重试时，指数退避 + full jitter 的基准时长与上限
+	retryBackoffBase time.Duration
+	retryBackoffCap  time.Duration
 }
 
 type LockOption func(*LockOptions)
@@ -82,6 +53,15 @@ func setLockOptions(lock *LockOptions) {
 		// 默认阻塞等待时间上限是5秒
 		lock.blockWaitingSeconds = 5
 	}
+	if lock.renewalRatio <= 0 {
+		lock.renewalRatio = DefaultRenewalRatio
+	}
+	if lock.retryBackoffBase <= 0 {
+		lock.retryBackoffBase = DefaultRetryBackoffBase
+	}
+	if lock.retryBackoffCap <= 0 {
+		lock.retryBackoffCap = DefaultRetryBackoffCap
+	}
 
 	// 倘若未设置分布式锁的过期时间，则会启动 watchdog
 	if lock.expireSeconds > 0 {
@@ -110,10 +90,66 @@ func WithExpireSeconds(expireSeconds int64) LockOption {
 	}
 }
 
+// WithReentrant 开启可重入模式：同一个 owner 可以多次 Lock，需调用相同次数的 Unlock 才会真正释放锁
+func WithReentrant() LockOption {
+	return func(o *LockOptions) {
+		o.reentrant = true
+	}
+}
+
+// WithOwner 显式指定锁持有者标识，用于可重入模式下实现跨协程的重入
+// （默认的 token 是进程+协程维度的，不指定 owner 时无法跨协程重入）
+func WithOwner(owner string) LockOption {
+	return func(o *LockOptions) {
+		o.owner = owner
+	}
+}
+
+// WithFencing 开启 fencing token：加锁成功后可通过 RedisLock.LockWithFence 获取一个单调递增的 token，
+// 下游存储可以凭借该 token 拒绝来自已过期锁持有者的写入（GC pause 超过 TTL 场景的典型应对手段）
+func WithFencing() LockOption {
+	return func(o *LockOptions) {
+		o.fencing = true
+	}
+}
+
+// WithWatchMode 开启 watch 模式：阻塞等待锁时，通过订阅 Pub/Sub 释放通知来触发重试，
+// 而不是固定间隔轮询，代价是需要为等待方额外维持一条长连接
+func WithWatchMode() LockOption {
+	return func(o *LockOptions) {
+		o.watchMode = true
+	}
+}
+
+// WithRenewalRatio 设置看门狗续约间隔相对 expireSeconds 的比例，默认 1/3，
+// 例如 expireSeconds=30 时每 10s 续约一次
+func WithRenewalRatio(renewalRatio float64) LockOption {
+	return func(o *LockOptions) {
+		o.renewalRatio = renewalRatio
+	}
+}
+
+// WithRetryBackoff 设置阻塞模式轮询重试时指数退避 + full jitter 的基准时长与上限，
+// 每次重试的等待时间为 rand(0, min(cap, base * 2^attempt))
+func WithRetryBackoff(base, cap time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.retryBackoffBase = base
+		o.retryBackoffCap = cap
+	}
+}
+
 // RedLockOptions 分布式锁实现
 type RedLockOptions struct {
 	singleNodesTimeout time.Duration
 	expireDuration     time.Duration
+	// fencing 为 true 时，每个节点都会开启 fencing token，RedLock.LockWithFence 取其中的最小值
+	fencing bool
+	// reentrant 为 true 时，每个节点都会开启可重入模式，同一个 owner 重复 Lock 只会增加重入计数
+	reentrant bool
+	// owner 可重入模式下的持有者标识，透传给每个节点的 RedisLock；留空时退化为各节点各自的 token
+	owner string
+	// clockDriftFactor 时钟漂移系数，用于计算 validity 时的漂移补偿：drift = expireDuration * clockDriftFactor + 2ms
+	clockDriftFactor float64
 }
 
 type RedLockOption func(options *RedLockOptions)
@@ -130,15 +166,43 @@ func WithRedLockExpireDuration(expireDuration time.Duration) RedLockOption {
 	}
 }
 
+// WithRedLockFencing 为 RedLock 下属的每个节点开启 fencing token
+func WithRedLockFencing() RedLockOption {
+	return func(o *RedLockOptions) {
+		o.fencing = true
+	}
+}
+
+// WithRedLockReentrant 为 RedLock 下属的每个节点开启可重入模式，owner 留空时各节点各自取自己的 token
+func WithRedLockReentrant(owner string) RedLockOption {
+	return func(o *RedLockOptions) {
+		o.reentrant = true
+		o.owner = owner
+	}
+}
+
+// WithClockDriftFactor 设置计算锁剩余有效期（validity）时使用的时钟漂移系数，默认 0.01（即 1%）
+func WithClockDriftFactor(clockDriftFactor float64) RedLockOption {
+	return func(o *RedLockOptions) {
+		o.clockDriftFactor = clockDriftFactor
+	}
+}
+
 func setRedLockOption(r *RedLockOptions) {
 	if r.singleNodesTimeout <= 0 {
 		r.singleNodesTimeout = DefaultSingleLockTimeout
 	}
+	if r.expireDuration <= 0 {
+		r.expireDuration = DefaultLockExpireSeconds * time.Second
+	}
+	if r.clockDriftFactor <= 0 {
+		r.clockDriftFactor = DefaultClockDriftFactor
+	}
 }
 
 type SingleNodeConf struct {
 	Network  string
 	Address  string
 	Password string
-	Opts     []ClientOption
+	Opts     []redigo.ClientOption
 }
@@ -34,44 +34,149 @@ func NewRedLock(key string, confs []*SingleNodeConf, opts ...RedLockOption) (*Re
 		// 要求所有节点累计的超时阈值要小于分布式锁过期时间的十分之一
 		return nil, errors.New("ERROR: expire thresholds of single node is too long")
 	}
+	if r.reentrant && r.fencing {
+		return nil, ErrReentrantFencingUnsupported
+	}
+
+	lockOpts := []LockOption{WithExpireSeconds(int64(r.expireDuration.Seconds()))}
+	if r.fencing {
+		lockOpts = append(lockOpts, WithFencing())
+	}
+	if r.reentrant {
+		lockOpts = append(lockOpts, WithReentrant())
+		if r.owner != "" {
+			lockOpts = append(lockOpts, WithOwner(r.owner))
+		}
+	}
 
 	r.locks = make([]*RedisLock, 0, len(confs))
 	r.successLocks = make([]*RedisLock, 0, len(confs))
 	for _, conf := range confs {
 		client := NewClient(conf.Network, conf.Address, conf.Password, conf.Opts...)
-		r.locks = append(r.locks, NewRedisLock(key, client, WithExpireSeconds(int64(r.expireDuration.Seconds()))))
+		lock, err := NewRedisLock(key, client, lockOpts...)
+		if err != nil {
+			return nil, err
+		}
+		r.locks = append(r.locks, lock)
 	}
 
 	return &r, nil
 }
 
-func (r *RedLock) Lock(ctx context.Context) error {
+// LockResult RedLock 加锁成功后的结果
+type LockResult struct {
+	// Validity 本次加锁的剩余安全有效期：expireDuration 扣除各节点加锁耗时与时钟漂移补偿后的余量。
+	// 调用方应当只在该有效期内持有并使用这把锁，超出后无法再保证互斥性
+	Validity time.Duration
+}
+
+type lockAttempt struct {
+	lock *RedisLock
+	err  error
+}
+
+// Lock 参照经典 Redlock 算法实现：并发地向所有节点发起加锁请求（而非串行），
+// 统计耗时后按 expireDuration - elapsed - drift 计算剩余有效期 validity，
+// 只有达成多数派且 validity 仍为正数时才视为加锁成功
+func (r *RedLock) Lock(ctx context.Context) (*LockResult, error) {
 	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := time.Now()
 
-	successCount := 0
+	resultCh := make(chan lockAttempt, len(r.locks))
 	for _, lock := range r.locks {
-		startTime := time.Now()
-		err := lock.Lock(ctx)
-		cost := time.Since(startTime)
-		if err == nil && cost <= r.singleNodesTimeout {
-			r.successLocks = append(r.successLocks, lock)
-			successCount++
+		lock := lock
+		go func() {
+			// 加锁前先探活，不健康的节点不必等满 singleNodesTimeout 才被判定失败；
+			// Ping 和加锁各自拥有完整的 singleNodesTimeout 预算，Ping 耗时不会挤占加锁本身的超时
+			pingCtx, pingCancel := context.WithTimeout(ctx, r.singleNodesTimeout)
+			err := lock.client.Ping(pingCtx)
+			pingCancel()
+			if err != nil {
+				resultCh <- lockAttempt{lock: lock, err: err}
+				return
+			}
+
+			lockCtx, lockCancel := context.WithTimeout(ctx, r.singleNodesTimeout)
+			defer lockCancel()
+			resultCh <- lockAttempt{lock: lock, err: lock.Lock(lockCtx)}
+		}()
+	}
+
+	overallDeadline := time.After(r.expireDuration / 2)
+	successLocks := make([]*RedisLock, 0, len(r.locks))
+	received := 0
+
+collect:
+	for received < len(r.locks) {
+		select {
+		case res := <-resultCh:
+			received++
+			if res.err == nil {
+				successLocks = append(successLocks, res.lock)
+			}
+		case <-overallDeadline:
+			break collect
+		}
+	}
+
+	// 对于超过整体截止时间仍未返回的节点，异步等待其结果并尽力而为地解锁，避免遗留孤儿锁
+	if pending := len(r.locks) - received; pending > 0 {
+		go func(pending int) {
+			for i := 0; i < pending; i++ {
+				res := <-resultCh
+				if res.err == nil {
+					res.lock.Unlock(context.Background())
+				}
+			}
+		}(pending)
+	}
+
+	elapsed := time.Since(start)
+	drift := time.Duration(float64(r.expireDuration)*r.clockDriftFactor) + 2*time.Millisecond
+	validity := r.expireDuration - elapsed - drift
+
+	quorum := len(r.locks)>>1 + 1
+	if len(successLocks) < quorum {
+		for _, lock := range successLocks {
+			lock.Unlock(ctx)
 		}
+		return nil, errors.New("ERROR: RedLock lock failed")
 	}
 
-	// 超过半数失败了
-	if successCount < (len(r.locks)>>1 + 1) {
-		// 对之前成功加锁的内容进行回滚
-		for _, lock := range r.successLocks {
+	if validity <= 0 {
+		for _, lock := range successLocks {
 			lock.Unlock(ctx)
 		}
-		r.mu.Unlock()
+		return nil, errors.New("ERROR: RedLock lock failed, validity expired before quorum was confirmed")
+	}
 
-		return errors.New("ERROR: RedLock lock failed")
+	r.successLocks = successLocks
+	return &LockResult{Validity: validity}, nil
+}
+
+// LockWithFence 加锁后返回所有加锁成功节点中 fencing token 的最小值，需配合 WithRedLockFencing() 使用。
+// 取最小值是因为下游存储只有在比“所有可能仍然存活的持有者”更新的 token 才能安全放行，
+// 而最小值是这批成功节点里最悲观（最旧）的那个
+func (r *RedLock) LockWithFence(ctx context.Context) (int64, error) {
+	if !r.fencing {
+		return 0, errors.New("ERROR: fencing token is not enabled, use WithRedLockFencing() option")
+	}
+	if _, err := r.Lock(ctx); err != nil {
+		return 0, err
 	}
 
-	r.mu.Unlock()
-	return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var minFence int64
+	for i, lock := range r.successLocks {
+		if i == 0 || lock.fenceToken < minFence {
+			minFence = lock.fenceToken
+		}
+	}
+	return minFence, nil
 }
 
 func (r *RedLock) UnLock(ctx context.Context) error {
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// GetProcessAndGoroutineIDStr 返回一个能唯一标识 "进程 + 协程" 的字符串
+// 用于给分布式锁生成默认的持有者标识（token）
+func GetProcessAndGoroutineIDStr() string {
+	return GetProcessID() + "_" + getGoroutineID()
+}
+
+// GetProcessID 获取当前进程 id
+func GetProcessID() string {
+	return strconv.Itoa(os.Getpid())
+}
+
+// getGoroutineID 获取当前协程 id
+// 通过截取 runtime.Stack 输出内容中的协程 id 部分实现，官方并未提供直接的 api
+func getGoroutineID() string {
+	buf := make([]byte, 128)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	stackInfo := string(bytes.TrimSpace(buf))
+	return strings.TrimSpace(strings.Split(strings.Split(stackInfo, "[running]")[0], "goroutine")[1])
+}